@@ -0,0 +1,74 @@
+package asebiten
+
+import (
+	"image"
+	"testing"
+	"time"
+)
+
+// threeFrameOnce builds an Animation with a single tag "atk" made of three 100ms frames, for exercising Once/Play
+// semantics. Frames carry a non-zero SourceRect (but no Image/Atlas) so that NewAnimation's isCPUSprite branch
+// allocates a real, non-empty gpuFrame backing texture instead of panicking on ebiten.NewImage(0, 0).
+func threeFrameOnce() *Animation {
+	rect := image.Rect(0, 0, 16, 16)
+	return NewAnimation(map[string][]AniFrame{
+		"atk": {
+			{FrameIdx: 0, DurationMillis: 100, SourceRect: rect},
+			{FrameIdx: 1, DurationMillis: 100, SourceRect: rect},
+			{FrameIdx: 2, DurationMillis: 100, SourceRect: rect},
+		},
+	})
+}
+
+// TestPlay_ReplayAfterEnd verifies that replaying a Once animation via Play, after it has already ended and idled
+// for a while, restarts at frame 0 and advances one frame at a time rather than jumping straight to the last frame
+// -- Play must reset elapsedMillis, since UpdateDelta keeps accumulating it while ended. Requires a usable ebiten
+// graphics context, same as any other ebiten.Image-touching test.
+func TestPlay_ReplayAfterEnd(t *testing.T) {
+	a := threeFrameOnce()
+	a.Play("atk", Once)
+
+	a.UpdateDelta(250 * time.Millisecond)
+	if a.FrameIdx() != 2 {
+		t.Fatalf("expected to reach last frame, got %d", a.FrameIdx())
+	}
+	if !a.ended {
+		t.Fatal("expected animation to have ended")
+	}
+
+	// Idle on the last frame for a long time; elapsedMillis would accumulate unboundedly here if nothing reset it.
+	a.UpdateDelta(5 * time.Second)
+
+	a.Play("atk", Once)
+	if a.FrameIdx() != 0 {
+		t.Fatalf("expected Play to restart at frame 0, got %d", a.FrameIdx())
+	}
+
+	a.UpdateDelta(50 * time.Millisecond)
+	if a.FrameIdx() != 0 {
+		t.Fatalf("expected to still be on frame 0 after 50ms of a 100ms frame, got %d", a.FrameIdx())
+	}
+}
+
+// TestSwapSheet_ClampsFrameWhenTagShrinks verifies that when a reloaded sheet's current tag still exists but has
+// fewer frames than before, swapSheet clamps currFrame to the new last frame and leaves currTag alone, instead of
+// bouncing the animation off to the default "" tag.
+func TestSwapSheet_ClampsFrameWhenTagShrinks(t *testing.T) {
+	a := threeFrameOnce()
+	a.SetTag("atk")
+	if err := a.SetFrame(2); err != nil {
+		t.Fatal(err)
+	}
+
+	shrunk := map[string][]AniFrame{
+		"atk": {{FrameIdx: 0, DurationMillis: 100, SourceRect: image.Rect(0, 0, 16, 16)}},
+	}
+	a.swapSheet(shrunk, SpriteSheet{})
+
+	if a.currTag != "atk" {
+		t.Fatalf("expected currTag to remain %q, got %q", "atk", a.currTag)
+	}
+	if a.FrameIdx() != 0 {
+		t.Fatalf("expected currFrame to clamp to the new last frame (0), got %d", a.FrameIdx())
+	}
+}
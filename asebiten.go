@@ -2,12 +2,12 @@ package asebiten
 
 import (
 	"errors"
+	"fmt"
 	"github.com/hajimehoshi/ebiten/v2"
 	"golang.org/x/exp/maps"
 	"golang.org/x/image/draw"
 	"image"
-	"log"
-	"sync"
+	"time"
 )
 
 // Animation is a collection of animations, keyed by a name called a 'tag'. Each tagged animation starts from its first
@@ -21,6 +21,22 @@ type Animation struct {
 	currTag   string
 	currFrame int
 
+	// mode controls what happens once currFrame reaches the end of the currently-playing tag. Set via Play;
+	// defaults to Loop.
+	mode PlayMode
+	// direction is the step applied to currFrame each frame advance; only meaningful for PingPong, which flips it
+	// at each boundary.
+	direction int
+	// ended is true once a Once or Hold animation has reached its last frame; further UpdateDelta calls no longer
+	// advance frames (they still accumulate elapsedMillis) until Play, SetTag or Restart clears it.
+	ended bool
+
+	// queuedTag/queuedMode/hasQueued record a PlayThen call; once mode's Once boundary is crossed, Play is called
+	// with these and hasQueued is cleared.
+	queuedTag  string
+	queuedMode PlayMode
+	hasQueued  bool
+
 	callbacks map[string]Callback
 
 	// FramesByTagName lists all frames, keyed by their tag. Take care when editing the images associated with this map,
@@ -35,6 +51,9 @@ type Animation struct {
 	needsDraw bool
 
 	elapsedMillis float64
+
+	// palette, when non-nil, recolors every pixel drawn by DrawTo. Set via SetPalette.
+	palette *Palette
 }
 
 func (r Rect) ImageRect() image.Rectangle {
@@ -52,15 +71,19 @@ type Size struct {
 	H int `json:"h"`
 }
 
-var tpsMut sync.Once
-var TPS int
-
-// SetTPS is automatically called before the first animation is drawn to screen. It must be explicitly called again
-// anytime that TPS is changed.
-func SetTPS() {
-	TPS = ebiten.TPS()
+// Printer is the logging interface asebiten writes diagnostic output to; *log.Logger satisfies it.
+type Printer interface {
+	Println(v ...interface{})
 }
 
+type noopPrinter struct{}
+
+func (noopPrinter) Println(v ...interface{}) {}
+
+// Logger receives asebiten's internal diagnostic output, such as frame-advance tracing from Animation.UpdateDelta.
+// It defaults to a no-op implementation; assign a different Printer (e.g. log.Default()) to enable it.
+var Logger Printer = noopPrinter{}
+
 // Clone creates a shallow clone of this animation which uses the same SpriteSheet as the original, but gets its own
 // callbacks and state. The tag, frame, and callbacks set on the source animation are copied for convenience. All timing
 // information is reset at the time the Animation is cloned.
@@ -71,6 +94,7 @@ func (a *Animation) Clone() Animation {
 		currTag:         a.currTag,
 		currFrame:       a.currFrame,
 		paused:          a.paused,
+		palette:         a.palette,
 	}
 }
 
@@ -78,9 +102,28 @@ func (a *Animation) Clone() Animation {
 func NewFlyweightAnimation(source *Animation) Animation {
 	return Animation{
 		FramesByTagName: source.FramesByTagName,
+		Source:          source.Source,
+		callbacks:       make(map[string]Callback),
 	}
 }
 
+// swapSheet atomically replaces this animation's frame data and source metadata, e.g. for AnimationManager.Reload.
+// If the currently-playing tag no longer exists in frames, playback resets to the default tag at frame 0. If the
+// tag still exists but has fewer frames than before, currFrame is clamped to the new last frame, and currTag is
+// left alone. In both cases paused and elapsedMillis are preserved.
+func (a *Animation) swapSheet(frames map[string][]AniFrame, source SpriteSheet) {
+	a.FramesByTagName = frames
+	a.Source = source
+	newFrames, ok := frames[a.currTag]
+	if !ok {
+		a.currTag = ""
+		a.currFrame = 0
+	} else if a.currFrame >= len(newFrames) {
+		a.currFrame = len(newFrames) - 1
+	}
+	a.needsDraw = true
+}
+
 // Callback is used for animation callbacks, which are triggered whenever an animation runs out of frames. All callbacks
 // are run synchronously on the same thread where Animation.Update() is called.
 type Callback func(*Animation)
@@ -101,6 +144,9 @@ func NewAnimation(anim map[string][]AniFrame) *Animation {
 	var rect image.Rectangle
 	for _, frames := range anim {
 		for _, frame := range frames {
+			if frame.Atlas != nil {
+				continue
+			}
 			if _, ok := frame.Image.(*ebiten.Image); !ok {
 				isCPUSprite = true
 			}
@@ -140,19 +186,68 @@ func (a *Animation) Toggle() {
 // Restart restarts the currently running animation from the beginning.
 func (a *Animation) Restart() {
 	a.currFrame = 0
+	a.direction = 1
+	a.elapsedMillis = 0
+	a.ended = false
 	a.needsDraw = true
 }
 
 // SetTag sets the currently running tag to the provided tag name. If the tag name is different from the currently
-// running tag, this func also sets the frame number to 0.
+// running tag, this func also sets the frame number to 0. The playback mode is left unchanged; use Play to set tag
+// and mode together.
 func (a *Animation) SetTag(tag string) {
 	if a.currTag != tag {
 		a.currFrame = 0
 	}
 	a.currTag = tag
+	a.direction = 1
+	a.ended = false
 	a.needsDraw = true
 }
 
+// PlayMode controls what an Animation does once it reaches the end of its currently-playing tag, independent of
+// whatever direction Aseprite baked into the tag via its "reverse"/"pingpong"/"pingpong_reverse" settings (those
+// simply reorder FramesByTagName before playback ever starts).
+type PlayMode int
+
+const (
+	// Loop repeats the animation indefinitely, wrapping back to frame 0. This is the default mode; SetTag keeps
+	// whatever mode was last set by Play.
+	Loop PlayMode = iota
+	// Once plays the animation through a single time, then holds on the last frame. If a PlayThen call queued a
+	// tag behind it, that tag starts playing as soon as the last frame is reached.
+	Once
+	// PingPong plays the animation forward to its last frame, then backward to its first frame, repeating
+	// indefinitely.
+	PingPong
+	// Hold plays the animation through a single time, then holds on the last frame permanently -- unlike Once, any
+	// tag queued behind it with PlayThen is never started.
+	Hold
+)
+
+// Play sets the currently running tag and playback mode, discarding any tag previously queued with PlayThen, and
+// restarts playback from frame 0 -- even if tag is already the currently-playing tag, so that replaying a tag that
+// previously ended (Once/Hold) starts over cleanly instead of immediately ending again.
+func (a *Animation) Play(tag string, mode PlayMode) {
+	a.currTag = tag
+	a.currFrame = 0
+	a.direction = 1
+	a.elapsedMillis = 0
+	a.ended = false
+	a.mode = mode
+	a.hasQueued = false
+	a.needsDraw = true
+}
+
+// PlayThen queues tag to start playing (in mode) as soon as the current animation's Once mode reaches its last
+// frame. It has no effect unless the current animation is playing in Once mode; in particular, queuing behind Hold
+// is a no-op, since Hold animations never advance to a queued tag.
+func (a *Animation) PlayThen(tag string, mode PlayMode) {
+	a.queuedTag = tag
+	a.queuedMode = mode
+	a.hasQueued = true
+}
+
 // OnEnd registers the provided Callback to run on the same frame that the final frame of the animation  is crossed.
 // Each Callback is called only once every time the animation ends, even if the animation ends multiple times during a
 // single frame. Callbacks for a given tag can be disabled by calling OnEnd(tag, nil).
@@ -163,33 +258,82 @@ func (a *Animation) OnEnd(tag string, callback Callback) {
 	a.callbacks[tag] = callback
 }
 
-// Update should be called once on every running animation each frame, only after calling asebiten.Update(). Calling
-// Update() on a paused animation immediately returns.
+// Update should be called once on every running animation each frame. It is a shim over UpdateDelta using Ebiten's
+// current TPS, and is equivalent to calling UpdateDelta(time.Second / time.Duration(ebiten.TPS())). Calling Update
+// on a paused animation immediately returns.
 func (a *Animation) Update() {
+	a.UpdateDelta(time.Second / time.Duration(ebiten.TPS()))
+}
+
+// UpdateDelta advances the animation by dt and is the primary way to drive an Animation; Update is a convenience
+// shim for the common case of being driven once per Ebiten tick. Driving an Animation with UpdateDelta directly
+// lets callers advance it from a fixed-step simulation, or apply effects such as slow-motion or fast-forward.
+// Calling UpdateDelta on a paused animation immediately returns.
+func (a *Animation) UpdateDelta(dt time.Duration) {
 	if a.paused {
 		return
 	}
-	tpsMut.Do(func() {
-		SetTPS()
-	})
 
-	a.elapsedMillis += 1 / float64(TPS) * 1000
+	a.elapsedMillis += float64(dt) / float64(time.Millisecond)
+	if a.ended {
+		return
+	}
 
 	// advance the current frame until you can't; this loop usually runs only once per tick
 	for a.elapsedMillis > float64(a.FramesByTagName[a.currTag][a.currFrame].DurationMillis) {
 		prior := a.elapsedMillis
 		a.elapsedMillis -= float64(a.FramesByTagName[a.currTag][a.currFrame].DurationMillis)
-		log.Println("elapsedMillis: ", prior, " - ", a.FramesByTagName[a.currTag][a.currFrame].DurationMillis, " = ", a.elapsedMillis)
-		a.currFrame = (a.currFrame + 1) % len(a.FramesByTagName[a.currTag])
+		Logger.Println("elapsedMillis: ", prior, " - ", a.FramesByTagName[a.currTag][a.currFrame].DurationMillis, " = ", a.elapsedMillis)
+		crossed := a.advanceFrame()
 		if a.gpuFrame != nil {
 			a.needsDraw = true
 		}
-		if a.currFrame != 0 || a.callbacks[a.currTag] == nil {
+		if !crossed {
 			continue
 		}
-		a.callbacks[a.currTag](a)
+		if a.callbacks[a.currTag] != nil {
+			a.callbacks[a.currTag](a)
+		}
+		if a.mode == Once && a.hasQueued {
+			tag, mode := a.queuedTag, a.queuedMode
+			a.hasQueued = false
+			a.Play(tag, mode)
+			break
+		}
+		if a.mode == Once || a.mode == Hold {
+			a.ended = true
+			break
+		}
+	}
+}
+
+// advanceFrame moves currFrame forward by one step according to a.mode, and reports whether the animation's end
+// boundary was crossed this step -- i.e. whether OnEnd and PlayThen chaining should fire.
+func (a *Animation) advanceFrame() bool {
+	frames := a.FramesByTagName[a.currTag]
+	switch a.mode {
+	case Once, Hold:
+		if a.currFrame == len(frames)-1 {
+			return true
+		}
+		a.currFrame++
+		return a.currFrame == len(frames)-1
+	case PingPong:
+		a.currFrame += a.direction
+		switch {
+		case a.currFrame >= len(frames)-1:
+			a.currFrame = len(frames) - 1
+			a.direction = -1
+		case a.currFrame <= 0:
+			a.currFrame = 0
+			a.direction = 1
+			return true
+		}
+		return false
+	default: // Loop
+		a.currFrame = (a.currFrame + 1) % len(frames)
+		return a.currFrame == 0
 	}
-	return
 }
 
 // DrawTo draws an animation from to the provided screen using the provided options. Does not automatically perform
@@ -198,7 +342,11 @@ func (a *Animation) Update() {
 func (a *Animation) DrawTo(screen *ebiten.Image, options *ebiten.DrawImageOptions) {
 	frame := a.FramesByTagName[a.currTag][a.currFrame]
 	if a.gpuFrame == nil {
-		screen.DrawImage(frame.Image.(*ebiten.Image), options)
+		if a.palette != nil {
+			a.palette.draw(screen, frame.ebitenImage(), options)
+			return
+		}
+		screen.DrawImage(frame.ebitenImage(), options)
 		return
 	}
 	if a.needsDraw {
@@ -206,9 +354,20 @@ func (a *Animation) DrawTo(screen *ebiten.Image, options *ebiten.DrawImageOption
 		draw.Draw(a.gpuFrame, frame.SourceRect, frame.Image, frame.Image.Bounds().Min, draw.Over)
 		a.needsDraw = false
 	}
+	if a.palette != nil {
+		a.palette.draw(screen, a.gpuFrame, options)
+		return
+	}
 	screen.DrawImage(a.gpuFrame, options)
 }
 
+// SetPalette assigns the named palette (previously registered with RegisterPalette) to this animation; DrawTo then
+// recolors every pixel it draws through that palette instead of drawing the frame's own colors. Passing the empty
+// string, or a name that was never registered, clears any palette and reverts to normal drawing.
+func (a *Animation) SetPalette(name string) {
+	a.palette = lookupPalette(name)
+}
+
 // DrawPackedTo draws a packed animation to the proveded screen. A func to manage any draw options is provided -- the
 // translations needed to unpack frames from packed sprite sheets have already been performed.
 func (a *Animation) DrawPackedTo(screen *ebiten.Image, optFunc func(options *ebiten.DrawImageOptions)) {
@@ -217,7 +376,7 @@ func (a *Animation) DrawPackedTo(screen *ebiten.Image, optFunc func(options *ebi
 	opts.GeoM.Translate(float64(frame.SourceRect.Min.X), float64(frame.SourceRect.Min.Y))
 	optFunc(&opts)
 	if a.gpuFrame == nil {
-		screen.DrawImage(frame.Image.(*ebiten.Image), &opts)
+		screen.DrawImage(frame.ebitenImage(), &opts)
 		return
 	}
 	if a.needsDraw {
@@ -230,7 +389,11 @@ func (a *Animation) DrawPackedTo(screen *ebiten.Image, optFunc func(options *ebi
 
 // Bounds retrieves the bounds of the current frame.
 func (a *Animation) Bounds() image.Rectangle {
-	return a.FramesByTagName[a.currTag][a.currFrame].Image.Bounds()
+	frame := a.FramesByTagName[a.currTag][a.currFrame]
+	if frame.Atlas != nil {
+		return frame.AtlasRect
+	}
+	return frame.Image.Bounds()
 }
 
 // FrameIdx retrieves the index of the current frame.
@@ -243,14 +406,129 @@ func (a *Animation) Frame() AniFrame {
 	return a.FramesByTagName[a.currTag][a.currFrame]
 }
 
+// Slice returns the bounds of the named Aseprite slice as of the current frame, resolving whichever SliceKey
+// applies (the key with the greatest Frame not exceeding the current frame's original Aseprite index). The second
+// return value is false if Source has no slice by that name.
+func (a *Animation) Slice(name string) (image.Rectangle, bool) {
+	key, ok := a.sliceKeyFor(name)
+	if !ok {
+		return image.Rectangle{}, false
+	}
+	return key.Bounds.ImageRect(), true
+}
+
+// DrawSliceTo draws the named slice of the current frame into dst on screen. If the slice has center data, it is
+// drawn as a 9-slice: the four corners are drawn at native size and the edges and center are stretched to fill dst.
+// Without center data, the whole slice is stretched to fill dst. Returns an error if Source has no slice by that
+// name.
+func (a *Animation) DrawSliceTo(screen *ebiten.Image, name string, dst image.Rectangle, opts *ebiten.DrawImageOptions) error {
+	key, ok := a.sliceKeyFor(name)
+	if !ok {
+		return fmt.Errorf("asebiten: no slice named %q", name)
+	}
+	frame := a.Frame()
+	img := frame.ebitenImage()
+	bounds := key.Bounds.ImageRect().Add(frameOrigin(frame))
+	if key.Center == nil {
+		drawStretched(screen, img.SubImage(bounds).(*ebiten.Image), bounds, dst, opts)
+		return nil
+	}
+	draw9Slice(screen, img, bounds, key.Center.ImageRect(), dst, opts)
+	return nil
+}
+
+// frameOrigin returns the offset from a slice's frame-local Aseprite coordinates to frame.ebitenImage().Bounds()'s
+// coordinate space. Ordinary per-frame textures are normalized back to a (0,0) origin by ebiten.NewImageFromImage,
+// so the offset is zero; frames backed by a shared PackedSpriteSheet atlas keep their SubImage bounds
+// atlas-absolute, so the offset is the frame's AtlasRect.Min.
+func frameOrigin(frame AniFrame) image.Point {
+	if frame.Atlas != nil {
+		return frame.AtlasRect.Min
+	}
+	return image.Point{}
+}
+
+// sliceKeyFor resolves the SliceKey that applies to the current frame for the named slice.
+func (a *Animation) sliceKeyFor(name string) (SliceKey, bool) {
+	for _, slice := range a.Source.Meta.Slices {
+		if slice.Name != name || len(slice.Keys) == 0 {
+			continue
+		}
+		best := slice.Keys[0]
+		for _, key := range slice.Keys {
+			if key.Frame <= a.Frame().FrameIdx && key.Frame >= best.Frame {
+				best = key
+			}
+		}
+		return best, true
+	}
+	return SliceKey{}, false
+}
+
+// drawStretched draws sub (cropped to src's bounds) scaled to fill dst.
+func drawStretched(screen, sub *ebiten.Image, src, dst image.Rectangle, opts *ebiten.DrawImageOptions) {
+	if src.Dx() == 0 || src.Dy() == 0 || dst.Dx() == 0 || dst.Dy() == 0 {
+		return
+	}
+	o := ebiten.DrawImageOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	o.GeoM.Scale(float64(dst.Dx())/float64(src.Dx()), float64(dst.Dy())/float64(src.Dy()))
+	o.GeoM.Translate(float64(dst.Min.X), float64(dst.Min.Y))
+	screen.DrawImage(sub, &o)
+}
+
+// draw9Slice draws the slice given by bounds (within img) into dst, keeping the border outside center at native
+// size and stretching center (given in coordinates relative to bounds) to fill whatever space remains.
+func draw9Slice(screen, img *ebiten.Image, bounds, center image.Rectangle, dst image.Rectangle, opts *ebiten.DrawImageOptions) {
+	centerAbs := center.Add(bounds.Min)
+
+	leftW, rightW := centerAbs.Min.X-bounds.Min.X, bounds.Max.X-centerAbs.Max.X
+	topH, bottomH := centerAbs.Min.Y-bounds.Min.Y, bounds.Max.Y-centerAbs.Max.Y
+
+	srcXs := [4]int{bounds.Min.X, centerAbs.Min.X, centerAbs.Max.X, bounds.Max.X}
+	srcYs := [4]int{bounds.Min.Y, centerAbs.Min.Y, centerAbs.Max.Y, bounds.Max.Y}
+	dstXs := [4]int{dst.Min.X, dst.Min.X + leftW, dst.Max.X - rightW, dst.Max.X}
+	dstYs := [4]int{dst.Min.Y, dst.Min.Y + topH, dst.Max.Y - bottomH, dst.Max.Y}
+
+	for row := 0; row < 3; row++ {
+		for col := 0; col < 3; col++ {
+			srcRect := image.Rect(srcXs[col], srcYs[row], srcXs[col+1], srcYs[row+1])
+			dstRect := image.Rect(dstXs[col], dstYs[row], dstXs[col+1], dstYs[row+1])
+			if srcRect.Empty() || dstRect.Empty() {
+				continue
+			}
+			drawStretched(screen, img.SubImage(srcRect).(*ebiten.Image), srcRect, dstRect, opts)
+		}
+	}
+}
+
 // AniFrame denotes a single frame of this animation.
 type AniFrame struct {
 	// FrameIdx is the original index of this frame from Aseprite.
 	FrameIdx int
-	// Image represents an image to use. For efficiency, it's recommended to use subimage for each frame.
+	// Image represents an image to use. For efficiency, it's recommended to use subimage for each frame. Unset when
+	// Atlas is non-nil.
 	Image image.Image
 	// DurationMillis represents the number of milliseconds this frame should be shown.
 	DurationMillis int64
 	// SourceRect is the source rectangle in the sprite sheet. Primarily used for packed sprites.
 	SourceRect image.Rectangle
+
+	// Atlas, when non-nil, is a shared texture that AtlasRect is a region of, as produced by LoadAnimation's
+	// PackedSpriteSheet option. Drawing reads AtlasRect out of Atlas via SubImage rather than allocating a
+	// dedicated texture per frame.
+	Atlas *ebiten.Image
+	// AtlasRect is this frame's region within Atlas. Only meaningful when Atlas is non-nil.
+	AtlasRect image.Rectangle
+}
+
+// ebitenImage returns the *ebiten.Image to draw for this frame: a cheap SubImage view into Atlas when this frame
+// belongs to a packed sprite sheet, or its own Image otherwise.
+func (f AniFrame) ebitenImage() *ebiten.Image {
+	if f.Atlas != nil {
+		return f.Atlas.SubImage(f.AtlasRect).(*ebiten.Image)
+	}
+	return f.Image.(*ebiten.Image)
 }
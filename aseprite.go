@@ -7,11 +7,14 @@ import (
 	"image"
 	"io/fs"
 	"path"
+	"regexp"
+	"sort"
 	"strings"
 )
 
-// SpriteSheet represents the json export format for an Aesprite sprite sheet, which has been exported with frames in an
-// *Array*.
+// SpriteSheet represents the json export format for an Aesprite sprite sheet. Aseprite can export the "frames" field
+// either as an *Array* or as a *Hash* keyed by filename; both are accepted transparently via UnmarshalJSON, with the
+// hash form sorted by the numeric suffix of its keys so that FrameTag.From/To indices continue to line up.
 type SpriteSheet struct {
 	Frames []*Frame `json:"frames"`
 	Meta   Meta     `json:"meta"`
@@ -24,6 +27,60 @@ type SpriteSheet struct {
 	Animations map[string]Animation
 }
 
+// frameKeySuffix matches the trailing run of digits in a hash-form frame key, e.g. "sprite 12.ase" -> "12".
+var frameKeySuffix = regexp.MustCompile(`(\d+)\D*$`)
+
+// UnmarshalJSON decodes the "frames" field of an Aseprite export, supporting both the array form
+// (`"frames": [...]`) and the hash form keyed by filename (`"frames": {"sprite 0.ase": {...}, ...}`). The hash form
+// has no inherent ordering, so entries are sorted by the numeric suffix parsed out of each key before being
+// flattened into SpriteSheet.Frames.
+func (s *SpriteSheet) UnmarshalJSON(data []byte) error {
+	type alias SpriteSheet
+	aux := struct {
+		Frames json.RawMessage `json:"frames"`
+		*alias
+	}{
+		alias: (*alias)(s),
+	}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	var arr []*Frame
+	if err := json.Unmarshal(aux.Frames, &arr); err == nil {
+		s.Frames = arr
+		return nil
+	}
+
+	var hash map[string]*Frame
+	if err := json.Unmarshal(aux.Frames, &hash); err != nil {
+		return fmt.Errorf("frames: expected an array or a hash of frames: %w", err)
+	}
+	keys := make([]string, 0, len(hash))
+	for k := range hash {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return frameKeyIndex(keys[i]) < frameKeyIndex(keys[j])
+	})
+	s.Frames = make([]*Frame, len(keys))
+	for i, k := range keys {
+		s.Frames[i] = hash[k]
+	}
+	return nil
+}
+
+// frameKeyIndex parses the numeric suffix out of a hash-form frame key, falling back to 0 if none is found.
+func frameKeyIndex(key string) int {
+	match := frameKeySuffix.FindStringSubmatch(key)
+	if match == nil {
+		return 0
+	}
+	var idx int
+	fmt.Sscanf(match[1], "%d", &idx)
+	return idx
+}
+
 type Meta struct {
 	App       string     `json:"app"`
 	Version   string     `json:"version"`
@@ -41,9 +98,24 @@ type Slice struct {
 	Keys []SliceKey `json:"keys"`
 }
 
+// SliceKey describes the slice's geometry starting at Frame, and holding until the next SliceKey for the same Slice.
 type SliceKey struct {
 	Frame  int  `json:"frame"`
 	Bounds Rect `json:"bounds"`
+
+	// Center, when present, marks the 9-slice region (in coordinates relative to Bounds) that should stretch to fill
+	// extra space; the border around it is kept at native size. Aseprite omits this field for slices with no
+	// 9-slice data.
+	Center *Rect `json:"center,omitempty"`
+	// Pivot is the slice's pivot point, in coordinates relative to Bounds. Aseprite omits this field unless the
+	// slice has a pivot set.
+	Pivot *Pos `json:"pivot,omitempty"`
+}
+
+// Pos is an integer (x, y) coordinate pair.
+type Pos struct {
+	X int `json:"x"`
+	Y int `json:"y"`
 }
 
 type FrameTag struct {
@@ -69,18 +141,39 @@ type Frame struct {
 	Duration         int  `json:"duration"`
 }
 
+// LoadOption configures optional behavior for LoadAnimation.
+type LoadOption func(*loadOptions)
+
+type loadOptions struct {
+	packed bool
+}
+
+// PackedSpriteSheet keeps the sprite sheet's source image as a single shared GPU atlas instead of allocating a
+// separate *ebiten.Image per frame (the default). Each AniFrame instead holds a cheap SubImage view into the atlas
+// via AniFrame.Atlas/AtlasRect. Recommended for sheets with many frames, since the default mode allocates a new
+// backing texture per frame and defeats Ebiten's draw-call batching.
+func PackedSpriteSheet() LoadOption {
+	return func(o *loadOptions) {
+		o.packed = true
+	}
+}
+
 // LoadAnimation loads a sprite from the provided filesystem, based on the provided json path. The image paths are
 // assumed to be found in the directory relative to the path passed in.
-func LoadAnimation(fs fs.FS, jsonPath string) (*Animation, error) {
+func LoadAnimation(fs fs.FS, jsonPath string, opts ...LoadOption) (*Animation, error) {
+	var o loadOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
 	sheet, err := LoadSpriteSheet(fs, jsonPath)
 	if err != nil {
 		return nil, err
 	}
 	var byTagName map[string][]AniFrame
 	if len(sheet.Meta.FrameTags) == 0 {
-		byTagName, err = loadNoTags(&sheet)
+		byTagName, err = loadNoTags(&sheet, o)
 	} else {
-		byTagName, err = loadWithTags(&sheet)
+		byTagName, err = loadWithTags(&sheet, o)
 	}
 	if err != nil {
 		return nil, err
@@ -90,38 +183,51 @@ func LoadAnimation(fs fs.FS, jsonPath string) (*Animation, error) {
 	return result, nil
 }
 
-func loadNoTags(sheet *SpriteSheet) (map[string][]AniFrame, error) {
-	byTagName := make(map[string][]AniFrame)
-	for idx, frame := range sheet.Frames {
-		img := ebiten.NewImageFromImage(sheet.Image.SubImage(frame.Frame.ImageRect()))
-		byTagName[""] = append(byTagName[""], AniFrame{
+// newAniFrame builds the AniFrame for sheet.Frames[idx], either as a packed view into sheet.Image or as its own
+// dedicated texture, depending on o.packed.
+func newAniFrame(sheet *SpriteSheet, idx int, o loadOptions) AniFrame {
+	frame := sheet.Frames[idx]
+	rect := frame.Frame.ImageRect()
+	if o.packed {
+		return AniFrame{
 			FrameIdx:       idx,
-			Image:          img,
+			Atlas:          sheet.Image,
+			AtlasRect:      rect,
 			DurationMillis: int64(frame.Duration),
-		})
+		}
+	}
+	return AniFrame{
+		FrameIdx:       idx,
+		Image:          ebiten.NewImageFromImage(sheet.Image.SubImage(rect)),
+		DurationMillis: int64(frame.Duration),
+	}
+}
+
+func loadNoTags(sheet *SpriteSheet, o loadOptions) (map[string][]AniFrame, error) {
+	byTagName := make(map[string][]AniFrame)
+	for idx := range sheet.Frames {
+		byTagName[""] = append(byTagName[""], newAniFrame(sheet, idx, o))
 	}
 	return byTagName, nil
 }
 
-func loadWithTags(sheet *SpriteSheet) (map[string][]AniFrame, error) {
-	byTagName, err := loadNoTags(sheet)
+func loadWithTags(sheet *SpriteSheet, o loadOptions) (map[string][]AniFrame, error) {
+	byTagName, err := loadNoTags(sheet, o)
 	if err != nil {
 		return nil, err
 	}
 	imgCache := make(map[int]*ebiten.Image)
 	for _, tag := range sheet.Meta.FrameTags {
 		for i := tag.From; i <= tag.To; i++ {
-			frame := sheet.Frames[i]
-			img, ok := imgCache[i]
-			if !ok {
-				img = ebiten.NewImageFromImage(sheet.Image.SubImage(frame.Frame.ImageRect()))
-				imgCache[i] = img
+			af := newAniFrame(sheet, i, o)
+			if !o.packed {
+				if img, ok := imgCache[i]; ok {
+					af.Image = img
+				} else {
+					imgCache[i] = af.Image.(*ebiten.Image)
+				}
 			}
-			byTagName[tag.Name] = append(byTagName[tag.Name], AniFrame{
-				FrameIdx:       i,
-				Image:          img,
-				DurationMillis: int64(frame.Duration),
-			})
+			byTagName[tag.Name] = append(byTagName[tag.Name], af)
 		}
 		switch tag.Direction {
 		case "reverse":
@@ -0,0 +1,111 @@
+package asebiten
+
+import (
+	"bytes"
+	"github.com/hajimehoshi/ebiten/v2"
+	"image"
+	"image/color"
+	"image/png"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// benchSheetFS builds an in-memory sprite sheet with n frames laid out in a single row, for benchmarking
+// LoadAnimation and Animation.DrawTo's allocation behavior with and without PackedSpriteSheet.
+func benchSheetFS(n int) fstest.MapFS {
+	const frameSize = 16
+	img := image.NewRGBA(image.Rect(0, 0, frameSize*n, frameSize))
+	for i := 0; i < n; i++ {
+		c := color.RGBA{R: byte(i), A: 255}
+		for y := 0; y < frameSize; y++ {
+			for x := 0; x < frameSize; x++ {
+				img.Set(i*frameSize+x, y, c)
+			}
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		panic(err)
+	}
+
+	frames := make([]string, n)
+	for i := range frames {
+		frames[i] = `{"frame":{"x":` + strconv.Itoa(i*frameSize) + `,"y":0,"w":` + strconv.Itoa(frameSize) +
+			`,"h":` + strconv.Itoa(frameSize) + `},"duration":100}`
+	}
+	js := `{"frames":[` + strings.Join(frames, ",") + `],"meta":{"version":"1.3","image":"sheet.png","size":{"w":` +
+		strconv.Itoa(frameSize*n) + `,"h":` + strconv.Itoa(frameSize) + `}}}`
+
+	return fstest.MapFS{
+		"sheet.json": {Data: []byte(js)},
+		"sheet.png":  {Data: buf.Bytes()},
+	}
+}
+
+// BenchmarkLoadAnimation compares the default per-frame-texture loading path against PackedSpriteSheet, which
+// should show markedly fewer allocations as the frame count grows. Requires a usable ebiten graphics context, same
+// as any other ebiten.Image-touching test.
+func BenchmarkLoadAnimation(b *testing.B) {
+	fsys := benchSheetFS(256)
+
+	b.Run("PerFrameTexture", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := LoadAnimation(fsys, "sheet.json"); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("PackedSpriteSheet", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := LoadAnimation(fsys, "sheet.json", PackedSpriteSheet()); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// BenchmarkDrawAnimations compares drawing n animations per simulated frame under the default per-frame-texture
+// path against PackedSpriteSheet, reporting allocations for both. Under PackedSpriteSheet every draw call reads from
+// the same shared atlas texture, which lets draws batch; each such draw also takes a fresh SubImage view of the
+// atlas, while the default path draws each frame's own texture directly.
+func BenchmarkDrawAnimations(b *testing.B) {
+	const n = 256
+	fsys := benchSheetFS(16)
+
+	loadAnims := func(opts ...LoadOption) []*Animation {
+		anims := make([]*Animation, n)
+		for i := range anims {
+			anim, err := LoadAnimation(fsys, "sheet.json", opts...)
+			if err != nil {
+				b.Fatal(err)
+			}
+			anims[i] = anim
+		}
+		return anims
+	}
+
+	drawAll := func(b *testing.B, anims []*Animation, screen *ebiten.Image) {
+		b.ReportAllocs()
+		opts := &ebiten.DrawImageOptions{}
+		for i := 0; i < b.N; i++ {
+			for _, anim := range anims {
+				anim.DrawTo(screen, opts)
+			}
+		}
+	}
+
+	b.Run("PerFrameTexture", func(b *testing.B) {
+		screen := ebiten.NewImage(16, 16)
+		drawAll(b, loadAnims(), screen)
+	})
+
+	b.Run("PackedSpriteSheet", func(b *testing.B) {
+		screen := ebiten.NewImage(16, 16)
+		drawAll(b, loadAnims(PackedSpriteSheet()), screen)
+	})
+}
@@ -0,0 +1,57 @@
+package asebiten
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// TestSpriteSheet_UnmarshalJSON_HashFrames verifies that the hash-keyed "frames" form is flattened into
+// SpriteSheet.Frames sorted by the numeric suffix of each key, rather than by the hash's (unspecified) iteration
+// order, so that FrameTag.From/To indices still line up with the array form.
+func TestSpriteSheet_UnmarshalJSON_HashFrames(t *testing.T) {
+	js := `{
+		"frames": {
+			"sprite 10.ase": {"frame":{"x":160,"y":0,"w":16,"h":16},"duration":100},
+			"sprite 2.ase":  {"frame":{"x":32,"y":0,"w":16,"h":16},"duration":100},
+			"sprite 1.ase":  {"frame":{"x":16,"y":0,"w":16,"h":16},"duration":100}
+		},
+		"meta": {"version":"1.3","image":"sheet.png","size":{"w":176,"h":16}}
+	}`
+
+	var sheet SpriteSheet
+	if err := json.Unmarshal([]byte(js), &sheet); err != nil {
+		t.Fatal(err)
+	}
+	if len(sheet.Frames) != 3 {
+		t.Fatalf("expected 3 frames, got %d", len(sheet.Frames))
+	}
+	wantXs := []int{16, 32, 160}
+	for i, want := range wantXs {
+		if got := sheet.Frames[i].Frame.X; got != want {
+			t.Errorf("frame %d: expected x=%d, got %d", i, want, got)
+		}
+	}
+}
+
+// TestSpriteSheet_UnmarshalJSON_ArrayFrames verifies the array form still decodes directly, unaffected by the
+// hash-form fallback path.
+func TestSpriteSheet_UnmarshalJSON_ArrayFrames(t *testing.T) {
+	js := `{
+		"frames": [
+			{"frame":{"x":0,"y":0,"w":16,"h":16},"duration":100},
+			{"frame":{"x":16,"y":0,"w":16,"h":16},"duration":100}
+		],
+		"meta": {"version":"1.3","image":"sheet.png","size":{"w":32,"h":16}}
+	}`
+
+	var sheet SpriteSheet
+	if err := json.Unmarshal([]byte(js), &sheet); err != nil {
+		t.Fatal(err)
+	}
+	if len(sheet.Frames) != 2 {
+		t.Fatalf("expected 2 frames, got %d", len(sheet.Frames))
+	}
+	if sheet.Frames[1].Frame.X != 16 {
+		t.Errorf("expected frame 1 x=16, got %d", sheet.Frames[1].Frame.X)
+	}
+}
@@ -0,0 +1,118 @@
+package asebiten
+
+import (
+	"io/fs"
+	"sync"
+	"time"
+)
+
+// AnimationManager owns a registry of animations loaded from a single fs.FS, keyed by the path they were loaded
+// from, and supports re-loading them at runtime via Reload for dev-time iteration on a .ase/.aseprite export.
+// AnimationManager is safe for concurrent use, except that Update must not be called concurrently with itself.
+type AnimationManager struct {
+	fsys fs.FS
+
+	mu     sync.RWMutex
+	sheets map[string]*managedSheet
+}
+
+// managedSheet tracks every live Animation sharing one loaded sprite sheet, so Reload can swap fresh frame data
+// onto all of them at once and Update can advance them together. source backs new flyweight clones (see Load): it
+// starts as the original Animation returned by the first Load for this sheet and is replaced by the freshly loaded
+// Animation on each Reload. It's kept separately from anims so it continues to back new clones even after the
+// original has been Release-d.
+type managedSheet struct {
+	opts   []LoadOption
+	source *Animation
+	anims  []*Animation
+}
+
+// NewAnimationManager creates an AnimationManager that loads animations from fsys.
+func NewAnimationManager(fsys fs.FS) *AnimationManager {
+	return &AnimationManager{
+		fsys:   fsys,
+		sheets: make(map[string]*managedSheet),
+	}
+}
+
+// Load returns an Animation for jsonPath, loading it from the manager's fs.FS the first time it's requested.
+// Subsequent calls for the same path return flyweight clones of the first load (see NewFlyweightAnimation), each
+// with its own playback state but sharing the underlying frame data -- and each kept up to date by Reload and
+// advanced by Update until it's passed to Release.
+func (m *AnimationManager) Load(jsonPath string, opts ...LoadOption) (*Animation, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	sheet, ok := m.sheets[jsonPath]
+	if !ok {
+		anim, err := LoadAnimation(m.fsys, jsonPath, opts...)
+		if err != nil {
+			return nil, err
+		}
+		m.sheets[jsonPath] = &managedSheet{opts: opts, source: anim, anims: []*Animation{anim}}
+		return anim, nil
+	}
+
+	clone := NewFlyweightAnimation(sheet.source)
+	sheet.anims = append(sheet.anims, &clone)
+	return &clone, nil
+}
+
+// Release stops anim (previously returned by Load for jsonPath) from being advanced by Update or refreshed by
+// Reload, so it and its frame data can be garbage-collected once the caller also drops its own reference. This is
+// the only way to bound memory for a sheet that's Load-ed repeatedly for short-lived entities (e.g. projectiles
+// spawned and destroyed every frame) -- Load on its own only ever grows the sheet's tracked instances. Release is a
+// no-op if jsonPath was never loaded through this manager, or if anim isn't currently tracked under it.
+func (m *AnimationManager) Release(jsonPath string, anim *Animation) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	sheet, ok := m.sheets[jsonPath]
+	if !ok {
+		return
+	}
+	for i, a := range sheet.anims {
+		if a == anim {
+			sheet.anims = append(sheet.anims[:i], sheet.anims[i+1:]...)
+			return
+		}
+	}
+}
+
+// Reload re-parses the JSON and image at path from the manager's fs.FS, then atomically swaps the resulting frame
+// data and source metadata onto every live Animation returned by Load for that path -- including the original and
+// every flyweight clone of it. On each one, currTag/currFrame/paused/elapsedMillis are preserved if currTag still
+// exists among the reloaded frames (with currFrame clamped to the tag's new last frame if the tag got shorter);
+// otherwise playback resets to the default tag at frame 0. Reload is a no-op,
+// returning nil, if path was never loaded through this manager.
+func (m *AnimationManager) Reload(path string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	sheet, ok := m.sheets[path]
+	if !ok {
+		return nil
+	}
+	fresh, err := LoadAnimation(m.fsys, path, sheet.opts...)
+	if err != nil {
+		return err
+	}
+	for _, anim := range sheet.anims {
+		anim.swapSheet(fresh.FramesByTagName, fresh.Source)
+	}
+	sheet.source = fresh
+	return nil
+}
+
+// Update advances every animation registered with this manager by dt in a single call. Update is not safe to call
+// concurrently with itself, though it may be called concurrently with Load, Release and Reload, which the manager's
+// lock keeps from overlapping with it.
+func (m *AnimationManager) Update(dt time.Duration) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, sheet := range m.sheets {
+		for _, anim := range sheet.anims {
+			anim.UpdateDelta(dt)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package asebiten
+
+import (
+	"testing"
+	"time"
+)
+
+// TestAnimationManager_LoadTwice_OnEnd verifies that a flyweight clone returned by a second Load call has a usable
+// callbacks map: OnEnd must not panic on it, since this is the AnimationManager's primary multi-instance-sharing
+// pattern. Requires a usable ebiten graphics context, same as any other ebiten.Image-touching test.
+func TestAnimationManager_LoadTwice_OnEnd(t *testing.T) {
+	fsys := benchSheetFS(4)
+	m := NewAnimationManager(fsys)
+
+	if _, err := m.Load("sheet.json"); err != nil {
+		t.Fatal(err)
+	}
+	clone, err := m.Load("sheet.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clone.OnEnd("", func(*Animation) {})
+}
+
+// TestAnimationManager_Reload_PreservesLoadOptions verifies that Reload re-parses a sheet with the same LoadOptions
+// it was originally Load-ed with, so a sheet loaded with PackedSpriteSheet stays atlas-backed (Atlas != nil) across
+// a reload instead of silently reverting to per-frame textures. Requires a usable ebiten graphics context, same as
+// any other ebiten.Image-touching test.
+func TestAnimationManager_Reload_PreservesLoadOptions(t *testing.T) {
+	fsys := benchSheetFS(4)
+	m := NewAnimationManager(fsys)
+
+	anim, err := m.Load("sheet.json", PackedSpriteSheet())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if anim.Frame().Atlas == nil {
+		t.Fatal("expected frame to be atlas-backed before reload")
+	}
+
+	if err := m.Reload("sheet.json"); err != nil {
+		t.Fatal(err)
+	}
+	if anim.Frame().Atlas == nil {
+		t.Fatal("expected frame to still be atlas-backed after Reload")
+	}
+}
+
+// TestAnimationManager_Release verifies that a released clone is no longer advanced by Update, and that Load can
+// still be called again for the same path afterward -- i.e. releasing a clone doesn't disturb the sheet's shared
+// source data.
+func TestAnimationManager_Release(t *testing.T) {
+	fsys := benchSheetFS(4)
+	m := NewAnimationManager(fsys)
+
+	if _, err := m.Load("sheet.json"); err != nil {
+		t.Fatal(err)
+	}
+	clone, err := m.Load("sheet.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m.Release("sheet.json", clone)
+	m.Update(time.Second)
+	if clone.FrameIdx() != 0 {
+		t.Fatalf("expected released clone to no longer be advanced, got frame %d", clone.FrameIdx())
+	}
+
+	if _, err := m.Load("sheet.json"); err != nil {
+		t.Fatalf("expected Load to keep working after a Release: %v", err)
+	}
+}
@@ -0,0 +1,219 @@
+package asebiten
+
+import (
+	"fmt"
+	"github.com/hajimehoshi/ebiten/v2"
+	"image/color"
+	"io/fs"
+	"sync"
+)
+
+// maxColorRemaps bounds how many entries NewColorPalette can pass to the remap shader; it's sized for the
+// team-color / player-color use case, not as a general-purpose color-quantization tool.
+const maxColorRemaps = 16
+
+// Palette is a recoloring table sampled by a Kage shader at draw time instead of rewriting the source image on the
+// CPU. It has two modes, matching the two kinds of source art asebiten sees in practice:
+//
+//   - Built via NewColorPalette, it holds up to maxColorRemaps (from, to) color pairs and works on ordinary RGBA
+//     sprite art -- any pixel within a small RGBA distance (0.02) of a "from" color is replaced with its paired "to"
+//     color.
+//   - Built via NewPalette/NewPaletteFromColorPalette/LoadDATPalette, it holds a 256-color LUT and requires source
+//     art that has already been authored with each pixel's palette index packed into its red channel (0-255); this
+//     is the convention used by indexed-sprite formats like Diablo's .DAT-paletted art.
+//
+// Assign a Palette to an Animation with Animation.SetPalette.
+type Palette struct {
+	// lut is set for the indexed-LUT mode; nil for the color-remap mode.
+	lut *ebiten.Image
+
+	// fromColors/toColors/numRemaps hold the color-remap mode's data; unused (numRemaps == 0) in LUT mode.
+	fromColors [maxColorRemaps][4]float32
+	toColors   [maxColorRemaps][4]float32
+	numRemaps  int
+}
+
+// NewColorPalette builds a Palette that replaces each key color in remap with its paired value, leaving every
+// other color untouched. Unlike the indexed constructors below, this works on ordinary RGBA sprite art -- e.g. the
+// PNGs LoadAnimation loads -- since it matches real source colors rather than an index packed into a channel. At
+// most maxColorRemaps entries are used; any beyond that are dropped (in unspecified map-iteration order) with a
+// warning written to Logger.
+func NewColorPalette(remap map[color.Color]color.Color) *Palette {
+	p := &Palette{}
+	for from, to := range remap {
+		if p.numRemaps >= maxColorRemaps {
+			Logger.Println("asebiten: NewColorPalette: dropping remaps beyond the first ", maxColorRemaps)
+			break
+		}
+		p.fromColors[p.numRemaps] = colorToVec4(from)
+		p.toColors[p.numRemaps] = colorToVec4(to)
+		p.numRemaps++
+	}
+	return p
+}
+
+func colorToVec4(c color.Color) [4]float32 {
+	r, g, b, a := c.RGBA()
+	return [4]float32{float32(r) / 0xffff, float32(g) / 0xffff, float32(b) / 0xffff, float32(a) / 0xffff}
+}
+
+// NewPalette builds an indexed-LUT Palette from an ordered list of colors; colors[i] becomes the color for palette
+// index i. At most 256 colors are used -- any beyond that are ignored. Requires source art whose pixels already
+// carry a palette index in their red channel; see the Palette doc comment. For ordinary sprite art, use
+// NewColorPalette instead.
+func NewPalette(colors []color.Color) *Palette {
+	lut := ebiten.NewImage(256, 1)
+	pix := make([]byte, 256*4)
+	for i, c := range colors {
+		if i >= 256 {
+			break
+		}
+		r, g, b, a := c.RGBA()
+		pix[i*4] = byte(r >> 8)
+		pix[i*4+1] = byte(g >> 8)
+		pix[i*4+2] = byte(b >> 8)
+		pix[i*4+3] = byte(a >> 8)
+	}
+	lut.WritePixels(pix)
+	return &Palette{lut: lut}
+}
+
+// NewPaletteFromColorPalette builds an indexed-LUT Palette from a color.Palette, such as the one returned when
+// decoding an indexed GIF or PNG. See NewPalette for the indexing requirement this places on source art.
+func NewPaletteFromColorPalette(p color.Palette) *Palette {
+	colors := make([]color.Color, len(p))
+	copy(colors, p)
+	return NewPalette(colors)
+}
+
+// LoadDATPalette loads a Diablo-style .DAT palette from path in fsys: 768 bytes of packed 3-byte RGB triples,
+// giving 256 fully-opaque colors for an indexed-LUT Palette. See NewPalette for the indexing requirement this
+// places on source art.
+func LoadDATPalette(fsys fs.FS, path string) (*Palette, error) {
+	data, err := fs.ReadFile(fsys, path)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) != 768 {
+		return nil, fmt.Errorf("asebiten: DAT palette must be 768 bytes, got %d", len(data))
+	}
+	colors := make([]color.Color, 256)
+	for i := range colors {
+		colors[i] = color.RGBA{R: data[i*3], G: data[i*3+1], B: data[i*3+2], A: 255}
+	}
+	return NewPalette(colors), nil
+}
+
+var (
+	palettesMu sync.RWMutex
+	palettes   = make(map[string]*Palette)
+)
+
+// RegisterPalette makes p available under name for Animation.SetPalette. Registering under a name that's already
+// in use replaces the existing palette.
+func RegisterPalette(name string, p *Palette) {
+	palettesMu.Lock()
+	defer palettesMu.Unlock()
+	palettes[name] = p
+}
+
+func lookupPalette(name string) *Palette {
+	palettesMu.RLock()
+	defer palettesMu.RUnlock()
+	return palettes[name]
+}
+
+var (
+	indexedPaletteShaderOnce sync.Once
+	indexedPaletteShader     *ebiten.Shader
+)
+
+// indexedPaletteShaderSrc remaps a source image's red channel, read as a palette index in [0, 255], through a
+// 256x1 LUT texture bound as the shader's second image.
+const indexedPaletteShaderSrc = `
+//kage:unit pixels
+package main
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	src := imageSrc0UnsafeAt(srcPos)
+	idx := floor(src.r*255.0 + 0.5)
+	return imageSrc1UnsafeAt(vec2(idx+0.5, 0.5)) * color
+}
+`
+
+func getIndexedPaletteShader() *ebiten.Shader {
+	indexedPaletteShaderOnce.Do(func() {
+		shader, err := ebiten.NewShader([]byte(indexedPaletteShaderSrc))
+		if err != nil {
+			panic(fmt.Errorf("asebiten: compiling indexed palette shader: %w", err))
+		}
+		indexedPaletteShader = shader
+	})
+	return indexedPaletteShader
+}
+
+var (
+	colorRemapShaderOnce sync.Once
+	colorRemapShader     *ebiten.Shader
+)
+
+// colorRemapShaderSrc replaces any pixel matching one of NumRemaps FromColors entries with its paired ToColors
+// entry, leaving every other pixel untouched.
+const colorRemapShaderSrc = `
+//kage:unit pixels
+package main
+
+var NumRemaps float
+var FromColors [16]vec4
+var ToColors [16]vec4
+
+func Fragment(dstPos vec4, srcPos vec2, color vec4) vec4 {
+	src := imageSrc0UnsafeAt(srcPos)
+	for i := 0; i < 16; i++ {
+		if float(i) >= NumRemaps {
+			break
+		}
+		if distance(src, FromColors[i]) < 0.02 {
+			return ToColors[i] * color
+		}
+	}
+	return src * color
+}
+`
+
+func getColorRemapShader() *ebiten.Shader {
+	colorRemapShaderOnce.Do(func() {
+		shader, err := ebiten.NewShader([]byte(colorRemapShaderSrc))
+		if err != nil {
+			panic(fmt.Errorf("asebiten: compiling color remap palette shader: %w", err))
+		}
+		colorRemapShader = shader
+	})
+	return colorRemapShader
+}
+
+// draw renders img onto screen with its colors remapped by p, honoring the GeoM, ColorScale, and Blend from
+// options (if non-nil).
+func (p *Palette) draw(screen, img *ebiten.Image, options *ebiten.DrawImageOptions) {
+	opts := &ebiten.DrawRectShaderOptions{}
+	if options != nil {
+		opts.GeoM = options.GeoM
+		opts.ColorScale = options.ColorScale
+		opts.Blend = options.Blend
+	}
+	opts.Images[0] = img
+	bounds := img.Bounds()
+
+	if p.lut != nil {
+		opts.Images[1] = p.lut
+		screen.DrawRectShader(bounds.Dx(), bounds.Dy(), getIndexedPaletteShader(), opts)
+		return
+	}
+
+	opts.Uniforms = map[string]interface{}{
+		"NumRemaps":  float32(p.numRemaps),
+		"FromColors": p.fromColors,
+		"ToColors":   p.toColors,
+	}
+	screen.DrawRectShader(bounds.Dx(), bounds.Dy(), getColorRemapShader(), opts)
+}
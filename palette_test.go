@@ -0,0 +1,36 @@
+package asebiten
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestNewColorPalette(t *testing.T) {
+	from := color.RGBA{R: 255, A: 255}
+	to := color.RGBA{B: 255, A: 255}
+	p := NewColorPalette(map[color.Color]color.Color{from: to})
+
+	if p.numRemaps != 1 {
+		t.Fatalf("expected 1 remap, got %d", p.numRemaps)
+	}
+	if p.fromColors[0] != colorToVec4(from) {
+		t.Errorf("fromColors[0] = %v, want %v", p.fromColors[0], colorToVec4(from))
+	}
+	if p.toColors[0] != colorToVec4(to) {
+		t.Errorf("toColors[0] = %v, want %v", p.toColors[0], colorToVec4(to))
+	}
+	if p.lut != nil {
+		t.Error("expected lut to be nil in color-remap mode")
+	}
+}
+
+func TestNewColorPalette_TruncatesAtMax(t *testing.T) {
+	remap := make(map[color.Color]color.Color)
+	for i := 0; i < maxColorRemaps+5; i++ {
+		remap[color.RGBA{R: byte(i), A: 255}] = color.RGBA{G: byte(i), A: 255}
+	}
+	p := NewColorPalette(remap)
+	if p.numRemaps != maxColorRemaps {
+		t.Fatalf("expected numRemaps to be capped at %d, got %d", maxColorRemaps, p.numRemaps)
+	}
+}
@@ -0,0 +1,64 @@
+package asebiten
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// slicedSheetFS builds a two-frame, 16x16-per-frame sprite sheet with a "test" slice inside frame 1, so that
+// packing the sheet into a shared atlas places frame 1's origin away from (0,0).
+func slicedSheetFS() fstest.MapFS {
+	const frameSize = 16
+	img := image.NewRGBA(image.Rect(0, 0, frameSize*2, frameSize))
+	want := color.RGBA{R: 200, G: 50, B: 50, A: 255}
+	for y := 0; y < frameSize; y++ {
+		for x := frameSize; x < frameSize*2; x++ {
+			img.Set(x, y, want)
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		panic(err)
+	}
+
+	js := `{"frames":[
+		{"frame":{"x":0,"y":0,"w":16,"h":16},"duration":100},
+		{"frame":{"x":16,"y":0,"w":16,"h":16},"duration":100}
+	],"meta":{"version":"1.3","image":"sheet.png","size":{"w":32,"h":16},
+	"slices":[{"name":"test","keys":[{"frame":1,"bounds":{"x":4,"y":4,"w":8,"h":8}}]}]}}`
+
+	return fstest.MapFS{
+		"sheet.json": {Data: []byte(js)},
+		"sheet.png":  {Data: buf.Bytes()},
+	}
+}
+
+// TestDrawSliceTo_PackedSpriteSheet guards against the slice geometry being read in frame-local coordinates while
+// the frame's image is a SubImage of a shared atlas with atlas-absolute bounds (see frameOrigin): without
+// translating the slice bounds by the frame's atlas origin, DrawSliceTo silently draws nothing for any frame other
+// than the first. Requires a usable ebiten graphics context, same as any other ebiten.Image-touching test.
+func TestDrawSliceTo_PackedSpriteSheet(t *testing.T) {
+	anim, err := LoadAnimation(slicedSheetFS(), "sheet.json", PackedSpriteSheet())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := anim.SetFrame(1); err != nil {
+		t.Fatal(err)
+	}
+
+	screen := ebiten.NewImage(8, 8)
+	if err := anim.DrawSliceTo(screen, "test", image.Rect(0, 0, 8, 8), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	r, g, b, a := screen.At(4, 4).RGBA()
+	if r>>8 != 200 || g>>8 != 50 || b>>8 != 50 || a>>8 != 255 {
+		t.Fatalf("DrawSliceTo drew nothing for a packed sprite sheet: got rgba(%d,%d,%d,%d)", r>>8, g>>8, b>>8, a>>8)
+	}
+}